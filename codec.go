@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/proto"
+
+	pb "warwickcodingsociety.com/producer/models/stockfeed"
+)
+
+// confluentMagicByte is prepended (along with a 4-byte schema ID) to every
+// payload produced in Confluent wire format, per the Schema Registry spec.
+const confluentMagicByte = 0x00
+
+// PayloadCodec turns a StockUpdate into the bytes that get produced to
+// Kafka. Adding a new wire format (e.g. MessagePack) requires a new codec
+// implementation, a case in newCodec below and an entry in allowedFormats
+// (main.go); the production loop never needs to change.
+type PayloadCodec interface {
+	Encode(topic string, msg *pb.StockUpdate) ([]byte, error)
+	ContentType() string
+	SchemaID() uint32
+}
+
+// jsonCodec reproduces the original plain-JSON wire format.
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+func (jsonCodec) SchemaID() uint32    { return 0 }
+
+func (jsonCodec) Encode(topic string, msg *pb.StockUpdate) ([]byte, error) {
+	jsonStruct := struct {
+		Ticker    string  `json:"ticker"`
+		Timestamp int64   `json:"timestamp"`
+		Price     float64 `json:"price"`
+	}{
+		Ticker:    topic,
+		Timestamp: msg.Timestamp,
+		Price:     float64(msg.Price) / 100.0,
+	}
+	return json.Marshal(jsonStruct)
+}
+
+// protobufCodec reproduces the original raw-Protobuf wire format (no
+// Confluent framing, no schema registry round-trip).
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+func (protobufCodec) SchemaID() uint32    { return 0 }
+
+func (protobufCodec) Encode(topic string, msg *pb.StockUpdate) ([]byte, error) {
+	return proto.Marshal(msg)
+}
+
+// avroCodec encodes a StockUpdate as plain (un-framed) Avro binary.
+type avroCodec struct {
+	codec *goavro.Codec
+}
+
+func newAvroCodec() (*avroCodec, error) {
+	c, err := goavro.NewCodec(stockUpdateAvroSchema)
+	if err != nil {
+		return nil, fmt.Errorf("compile avro schema: %w", err)
+	}
+	return &avroCodec{codec: c}, nil
+}
+
+func (a *avroCodec) ContentType() string { return "application/avro" }
+func (a *avroCodec) SchemaID() uint32    { return 0 }
+
+func (a *avroCodec) Encode(topic string, msg *pb.StockUpdate) ([]byte, error) {
+	native := map[string]interface{}{
+		"timestamp": msg.Timestamp,
+		"price":     msg.Price,
+	}
+	binary, err := a.codec.BinaryFromNative(nil, native)
+	return binary, err
+}
+
+// confluentFramed wraps another codec and prepends the 5-byte Confluent
+// wire-format header (magic byte + big-endian schema ID) so the output can
+// be read by Kafka Connect, ksqlDB, or any Confluent-aware consumer.
+type confluentFramed struct {
+	inner    PayloadCodec
+	schemaID uint32
+}
+
+func (c *confluentFramed) ContentType() string { return c.inner.ContentType() }
+func (c *confluentFramed) SchemaID() uint32    { return c.schemaID }
+
+func (c *confluentFramed) Encode(topic string, msg *pb.StockUpdate) ([]byte, error) {
+	payload, err := c.inner.Encode(topic, msg)
+	if err != nil {
+		return nil, err
+	}
+	framed := make([]byte, 5+len(payload))
+	framed[0] = confluentMagicByte
+	binary.BigEndian.PutUint32(framed[1:5], c.schemaID)
+	copy(framed[5:], payload)
+	return framed, nil
+}
+
+// stockUpdateAvroSchema is the Avro equivalent of pb.StockUpdate. It is
+// also what gets registered against the Schema Registry under
+// "<ticker>-value" when -schema-registry is used with an Avro format.
+const stockUpdateAvroSchema = `{
+	"type": "record",
+	"name": "StockUpdate",
+	"namespace": "com.warwickcodingsociety.producer",
+	"fields": [
+		{"name": "timestamp", "type": "long"},
+		{"name": "price", "type": "int"}
+	]
+}`
+
+// stockUpdateProtoSchema is the .proto source registered against the
+// Schema Registry for protobuf-format topics.
+const stockUpdateProtoSchema = `syntax = "proto3";
+
+package stockfeed;
+
+option go_package = "warwickcodingsociety.com/producer/models/stockfeed";
+
+message StockUpdate {
+  int64 timestamp = 1;
+  int32 price = 2;
+}
+`
+
+// newCodec builds the PayloadCodec for the given -format value. When
+// registryClient is non-nil, protobuf/avro payloads are wrapped in
+// Confluent framing with a schema ID resolved (registering the schema on
+// first use) against the Schema Registry for the given subject.
+func newCodec(format string, registryClient *schemaRegistryClient, subject string) (PayloadCodec, error) {
+	switch format {
+	case "json":
+		return jsonCodec{}, nil
+
+	case "protobuf":
+		var codec PayloadCodec = protobufCodec{}
+		if registryClient != nil {
+			schemaID, err := registryClient.register(subject, stockUpdateProtoSchema, "PROTOBUF")
+			if err != nil {
+				return nil, fmt.Errorf("register protobuf schema: %w", err)
+			}
+			codec = &confluentFramed{inner: codec, schemaID: schemaID}
+		}
+		return codec, nil
+
+	case "avro":
+		inner, err := newAvroCodec()
+		if err != nil {
+			return nil, err
+		}
+		var codec PayloadCodec = inner
+		if registryClient != nil {
+			schemaID, err := registryClient.register(subject, stockUpdateAvroSchema, "AVRO")
+			if err != nil {
+				return nil, fmt.Errorf("register avro schema: %w", err)
+			}
+			codec = &confluentFramed{inner: codec, schemaID: schemaID}
+		}
+		return codec, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// codecResolver hands out a PayloadCodec per topic, registering (and
+// caching) a distinct Schema Registry subject for each one so a
+// multi-ticker run produces every symbol under its own "<ticker>-value"
+// schema rather than sharing the first symbol's schema ID.
+type codecResolver struct {
+	format          string
+	registryClient  *schemaRegistryClient
+	subjectOverride string // from -subject; when set, every topic shares this one subject instead
+	cache           map[string]PayloadCodec
+}
+
+// newCodecResolver validates the requested format once up front (so a bad
+// -format or an unparseable Avro schema fails fast at startup) and returns
+// a resolver that builds the real, possibly registry-backed codec for each
+// topic lazily on first use.
+func newCodecResolver(format string, registryClient *schemaRegistryClient, subjectOverride string) (*codecResolver, error) {
+	if _, err := newCodec(format, nil, ""); err != nil {
+		return nil, err
+	}
+	return &codecResolver{
+		format:          format,
+		registryClient:  registryClient,
+		subjectOverride: subjectOverride,
+		cache:           make(map[string]PayloadCodec),
+	}, nil
+}
+
+// forTopic returns the codec for topic, building (and registering its
+// schema, if applicable) on first use and reusing it thereafter.
+func (r *codecResolver) forTopic(topic string) (PayloadCodec, error) {
+	if codec, ok := r.cache[topic]; ok {
+		return codec, nil
+	}
+
+	subject := r.subjectOverride
+	if subject == "" {
+		subject = topic + "-value"
+	}
+
+	codec, err := newCodec(r.format, r.registryClient, subject)
+	if err != nil {
+		return nil, err
+	}
+	r.cache[topic] = codec
+	return codec, nil
+}
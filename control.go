@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"google.golang.org/protobuf/proto"
+
+	pb "warwickcodingsociety.com/producer/models/stockfeed"
+)
+
+var (
+	controlTopic      string
+	heartbeatInterval time.Duration
+	shutdownTimeoutMs int
+)
+
+func init() {
+	flag.StringVar(&controlTopic, "control-topic", "control", "OPTIONAL: Topic that SESSION_START/HEARTBEAT/SESSION_END/MARKET_OPEN/MARKET_CLOSE control messages are produced to.")
+	flag.DurationVar(&heartbeatInterval, "heartbeat-interval", 30*time.Second, "OPTIONAL: How often to emit a HEARTBEAT control message, even when no tick fires.")
+	flag.IntVar(&shutdownTimeoutMs, "shutdown-timeout-ms", 5000, "OPTIONAL: How long to wait for in-flight deliveries to flush on SIGINT/SIGTERM.")
+}
+
+// newSessionID returns a monotonically increasing session identifier
+// (wall-clock nanoseconds), unique across restarts of this producer.
+func newSessionID() int64 {
+	return time.Now().UnixNano()
+}
+
+// emitControlMessage marshals and produces a single StockControl event.
+func emitControlMessage(p *kafka.Producer, sessionID int64, eventType pb.StockControl_EventType) {
+	topic := controlTopic
+	msg := &pb.StockControl{
+		SessionId: sessionID,
+		EventType: eventType,
+		Timestamp: time.Now().UnixNano(),
+	}
+
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		log.Printf("Failed to marshal control message %v: %v", eventType, err)
+		return
+	}
+
+	if err := p.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Value:          value,
+	}, nil); err != nil {
+		log.Printf("Failed to produce control message %v: %v", eventType, err)
+		return
+	}
+
+	log.Printf("Emitted control message %v (session=%d) to topic %s", eventType, sessionID, topic)
+}
+
+// runHeartbeatLoop emits a HEARTBEAT on every tick of -heartbeat-interval
+// until stop is closed, so consumers can tell "no trades" apart from
+// "producer dead" even during a quiet market.
+func runHeartbeatLoop(p *kafka.Producer, sessionID int64, stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emitControlMessage(p, sessionID, pb.StockControl_HEARTBEAT)
+		case <-stop:
+			return
+		}
+	}
+}
@@ -1,74 +1,91 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
-	// Import the generated protobuf package
-	"google.golang.org/protobuf/proto"
 
 	// NOTE: Adjust the path below if your generated file is elsewhere
 	pb "warwickcodingsociety.com/producer/models/stockfeed"
 )
 
-// Global state for mean-reverting price generation
-const (
-	MEAN_PRICE     = 100.0
-	REVERSION_RATE = 0.05
-	VOLATILITY     = 5.0
-)
-
 var (
 	// Command-line flags
-	tickerName string
-	rateMsgsPs int
-	brokerAddr string
-	formatType string // New flag for output format
-
-	// State for the price generator
-	currentPrice float64
+	tickerName     string
+	configPath     string
+	rateMsgsPs     int
+	brokerAddr     string
+	formatType     string // New flag for output format
+	schemaRegistry string // Schema Registry URL; enables Confluent-framed output
+	schemaSubject  string // Subject to register/fetch under; defaults to "<ticker>-value"
 )
 
-var allowedTickers = []string{"STK_ONE", "STK_TWO"}
-var allowedFormats = []string{"json", "protobuf"}
+var allowedFormats = []string{"json", "protobuf", "avro"}
 
 func init() {
 	// Configure command-line flags (No defaults, set to zero values)
-	flag.StringVar(&tickerName, "ticker", "", "MANDATORY: The name of the ticker (and Kafka topic) to produce to. Must be one of: STK_ONE, STK_TWO.")
-	flag.IntVar(&rateMsgsPs, "rate", 0, "MANDATORY: The rate of data production in messages per second.")
+	flag.StringVar(&tickerName, "ticker", "", "MANDATORY unless -replay is set: Comma-separated list of tickers (and Kafka topics) to produce to, or 'all' for every symbol in -config.")
+	flag.StringVar(&configPath, "config", "", "MANDATORY unless -replay is set: Path to the JSON universe config (symbols + correlation matrix).")
+	flag.IntVar(&rateMsgsPs, "rate", 0, "MANDATORY unless -replay is set with -replay-mode realtime: The rate of data production in messages per second (per symbol; with -replay-mode rate, rows per second).")
 	flag.StringVar(&brokerAddr, "broker", "", "MANDATORY: The Kafka broker address (e.g., my-kafka-service:9092).")
-	flag.StringVar(&formatType, "format", "", "MANDATORY: The output format. Must be one of: 'json' or 'protobuf'.")
+	flag.StringVar(&formatType, "format", "", "MANDATORY: The output format. Must be one of: 'json', 'protobuf', or 'avro'.")
+	flag.StringVar(&schemaRegistry, "schema-registry", "", "OPTIONAL: Confluent Schema Registry URL. When set, protobuf/avro payloads are registered and produced with Confluent wire-format framing.")
+	flag.StringVar(&schemaSubject, "subject", "", "OPTIONAL: Schema Registry subject to register under. Defaults to '<ticker>-value'.")
 
-	// Initialize random seed and starting price...
+	// Initialize random seed...
 	rand.Seed(time.Now().UnixNano())
-	currentPrice = MEAN_PRICE
 }
 
-func generatePrice() float64 {
-	diffFromMean := MEAN_PRICE - currentPrice
-	meanReversionPull := diffFromMean * REVERSION_RATE
-	randomStep := rand.NormFloat64() * VOLATILITY
-	priceChange := meanReversionPull + randomStep
+// selectUniverse resolves the -ticker flag ("all", or a comma-separated
+// list) against the loaded universe config and returns the sub-universe
+// (symbols + matching correlation submatrix) that should be simulated.
+func selectUniverse(cfg *UniverseConfig, tickerFlag string) (*UniverseConfig, error) {
+	if strings.EqualFold(strings.TrimSpace(tickerFlag), "all") {
+		return cfg, nil
+	}
+
+	indexByName := make(map[string]int, len(cfg.Symbols))
+	for i, sym := range cfg.Symbols {
+		indexByName[sym.Name] = i
+	}
 
-	currentPrice += priceChange
-	if currentPrice <= 0 {
-		currentPrice = 0.01
+	var indices []int
+	for _, name := range strings.Split(tickerFlag, ",") {
+		name = strings.TrimSpace(name)
+		idx, ok := indexByName[name]
+		if !ok {
+			return nil, fmt.Errorf("ticker %q is not defined in the universe config", name)
+		}
+		indices = append(indices, idx)
+	}
+
+	symbols := make([]Symbol, len(indices))
+	correlation := make([][]float64, len(indices))
+	for row, i := range indices {
+		symbols[row] = cfg.Symbols[i]
+		correlation[row] = make([]float64, len(indices))
+		for col, j := range indices {
+			correlation[row][col] = cfg.Correlation[i][j]
+		}
 	}
-	return currentPrice
+
+	return &UniverseConfig{Symbols: symbols, Correlation: correlation}, nil
 }
 
-// produceMessage creates the data and sends it to Kafka
-func produceMessage(p *kafka.Producer, format string) {
-	// Generate a new price and convert it to int32
-	priceFloat := generatePrice()
-	priceInt := int32(priceFloat * 100)
+// produceMessage creates the data for one symbol and sends it to Kafka via
+// the codec resolved for topic (so multi-ticker/replay runs register and
+// use a distinct schema per topic rather than sharing one).
+func produceMessage(p *kafka.Producer, codecs *codecResolver, topic string, price float64) {
+	produceStart := time.Now()
+	priceInt := int32(price * 100)
 
 	// *** CRITICAL CHANGE: Get the timestamp in nanoseconds ***
 	nanos := time.Now().UnixNano()
@@ -79,57 +96,38 @@ func produceMessage(p *kafka.Producer, format string) {
 		Price:     priceInt,
 	}
 
-	var value []byte
-	var err error
-
-	// --- Conditional Marshalling Logic ---
-	if strings.ToLower(format) == "protobuf" {
-		// Marshal to Protobuf Binary
-		value, err = proto.Marshal(messagePayload)
-		if err != nil {
-			log.Printf("Failed to marshal Protobuf: %v", err)
-			return
-		}
-		log.Printf("Produced Protobuf message to topic %s: Price: %.2f (Nanos: %d)",
-			tickerName, priceFloat, nanos)
-
-	} else { // JSON format
-		// Use a temporary struct for JSON output, ensuring it also uses nanoseconds
-		jsonStruct := struct {
-			Ticker    string  `json:"ticker"`
-			Timestamp int64   `json:"timestamp"` // int64 can hold nanoseconds
-			Price     float64 `json:"price"`
-		}{
-			Ticker:    tickerName,
-			Timestamp: nanos, // *** USING NANOSECONDS FOR JSON TOO ***
-			Price:     priceFloat,
-		}
-
-		// Marshal to JSON
-		value, err = json.Marshal(jsonStruct)
-		if err != nil {
-			log.Printf("Failed to marshal JSON: %v", err)
-			return
-		}
-		log.Printf("Produced JSON message to topic %s: Price: %.2f (Nanos: %d)",
-			tickerName, priceFloat, nanos)
+	codec, err := codecs.forTopic(topic)
+	if err != nil {
+		log.Printf("Failed to resolve codec for topic %s: %v", topic, err)
+		serializationErrorsTotal.WithLabelValues(topic).Inc()
+		return
 	}
 
-	// Check for marshalling error
+	value, err := codec.Encode(topic, messagePayload)
 	if err != nil {
+		log.Printf("Failed to encode message (%s): %v", codec.ContentType(), err)
+		serializationErrorsTotal.WithLabelValues(topic).Inc()
 		return
 	}
+	log.Printf("Produced %s message to topic %s: Price: %.2f (Nanos: %d)",
+		codec.ContentType(), topic, price, nanos)
 
 	// Produce the message to the topic
 	err = p.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &tickerName, Partition: kafka.PartitionAny},
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
 		Value:          value,
-		Key:            []byte(tickerName),
+		Key:            []byte(topic),
+		Opaque:         produceStart,
 	}, nil)
 
 	if err != nil {
 		log.Printf("Failed to produce message: %v", err)
+		return
 	}
+
+	messagesProducedTotal.WithLabelValues(topic).Inc()
+	bytesProducedTotal.WithLabelValues(topic).Add(float64(len(value)))
+	simulatedPrice.WithLabelValues(topic).Set(price)
 }
 
 // deliveryReportHandler asynchronously handles delivery reports from Kafka
@@ -137,8 +135,14 @@ func deliveryReportHandler(p *kafka.Producer) {
 	for e := range p.Events() {
 		switch ev := e.(type) {
 		case *kafka.Message:
+			topic := *ev.TopicPartition.Topic
 			if ev.TopicPartition.Error != nil {
 				log.Printf("Delivery failed: %v", ev.TopicPartition.Error)
+				deliveryFailuresTotal.WithLabelValues(topic).Inc()
+				continue
+			}
+			if produceStart, ok := ev.Opaque.(time.Time); ok {
+				produceLatencySeconds.WithLabelValues(topic).Observe(time.Since(produceStart).Seconds())
 			}
 		}
 	}
@@ -147,14 +151,6 @@ func deliveryReportHandler(p *kafka.Producer) {
 func main() {
 	flag.Parse()
 
-	isValidTicker := false
-	for _, allowed := range allowedTickers {
-		if tickerName == allowed {
-			isValidTicker = true
-			break
-		}
-	}
-
 	isValidFormat := false
 	for _, allowed := range allowedFormats {
 		if formatType == allowed {
@@ -162,14 +158,6 @@ func main() {
 			break
 		}
 	}
-
-	if !isValidTicker {
-		allowedList := strings.Join(allowedTickers, ", ")
-		fmt.Printf("❌ Error: Invalid ticker name '%s'. Allowed tickers are: %s\n", tickerName, allowedList)
-		flag.Usage() // Print usage help
-		os.Exit(1)
-	}
-
 	if !isValidFormat {
 		allowedList := strings.Join(allowedFormats, ", ")
 		fmt.Printf("❌ Error: Invalid format name '%s'. Allowed formats are: %s\n", formatType, allowedList)
@@ -177,24 +165,122 @@ func main() {
 		os.Exit(1)
 	}
 
+	replaying := replayFile != ""
+
+	var active *UniverseConfig
+	if !replaying {
+		universe, err := loadUniverseConfig(configPath)
+		if err != nil {
+			fmt.Printf("❌ Error: Failed to load universe config: %s\n", err)
+			os.Exit(1)
+		}
+
+		active, err = selectUniverse(universe, tickerName)
+		if err != nil {
+			fmt.Printf("❌ Error: %s\n", err)
+			flag.Usage() // Print usage help
+			os.Exit(1)
+		}
+	}
+
 	log.Printf("🚀 Starting Kafka Producer")
-	log.Printf("   -> Ticker/Topic: %s", tickerName)
+	if replaying {
+		log.Printf("   -> Replay File: %s (mode: %s, speed: %.2fx)", replayFile, replayMode, replaySpeed)
+	} else {
+		activeNames := make([]string, len(active.Symbols))
+		for i, sym := range active.Symbols {
+			activeNames[i] = sym.Name
+		}
+		log.Printf("   -> Tickers/Topics: %s", strings.Join(activeNames, ", "))
+		log.Printf("   -> Production Rate: %d msg/sec/symbol", rateMsgsPs)
+	}
 	log.Printf("   -> Broker Address: %s", brokerAddr)
-	log.Printf("   -> Production Rate: %d msg/sec", rateMsgsPs)
 	log.Printf("   -> Output Format: %s", strings.ToUpper(formatType)) // Highlight the chosen format
+	logProducerSettings()
+	startMetricsServer()
+
+	// --- Resolve the payload codec(s): one per topic, each under its own
+	// Schema Registry subject, unless -subject pins every topic to one. ---
+	var registryClient *schemaRegistryClient
+	if schemaRegistry != "" {
+		registryClient = newSchemaRegistryClient(schemaRegistry)
+		if schemaSubject != "" {
+			log.Printf("   -> Schema Registry: %s (subject: %s)", schemaRegistry, schemaSubject)
+		} else {
+			log.Printf("   -> Schema Registry: %s (subject: <ticker>-value, per topic)", schemaRegistry)
+		}
+	}
+	codecs, err := newCodecResolver(formatType, registryClient, schemaSubject)
+	if err != nil {
+		fmt.Printf("Failed to initialise codec: %s\n", err)
+		os.Exit(1)
+	}
+
+	var sim *Simulator
+	if !replaying {
+		sim, err = newSimulator(active)
+		if err != nil {
+			fmt.Printf("Failed to initialise simulator: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// --- Create the Kafka Producer ---
-	p, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": brokerAddr})
+	producerConfig, err := buildProducerConfig()
+	if err != nil {
+		fmt.Printf("Failed to build producer config: %s\n", err)
+		os.Exit(1)
+	}
+	p, err := kafka.NewProducer(producerConfig)
 	if err != nil {
 		fmt.Printf("Failed to create producer: %s\n", err)
 		os.Exit(1)
 	}
 	defer p.Close()
 
+	txnGate, err := newTransactionGate(p)
+	if err != nil {
+		fmt.Printf("Failed to start transactional producer: %s\n", err)
+		os.Exit(1)
+	}
+
 	// --- Start Delivery Report Handler in a Goroutine ---
 	go deliveryReportHandler(p)
 
+	// --- Session bookkeeping: announce SESSION_START and keep emitting HEARTBEATs ---
+	sessionID := newSessionID()
+	log.Printf("   -> Session ID: %d", sessionID)
+	emitControlMessage(p, sessionID, pb.StockControl_SESSION_START)
+
+	stopHeartbeat := make(chan struct{})
+	go runHeartbeatLoop(p, sessionID, stopHeartbeat)
+
+	shutdownSignal := make(chan os.Signal, 1)
+	signal.Notify(shutdownSignal, syscall.SIGINT, syscall.SIGTERM)
+
+	if replaying {
+		src, closer, err := openReplaySource(replayFile)
+		if err != nil {
+			fmt.Printf("Failed to open replay source: %s\n", err)
+			os.Exit(1)
+		}
+		defer closer.Close()
+
+		log.Printf("Starting replay loop from %s... Press Ctrl+C to stop.", replayFile)
+		if err := runReplayLoop(p, codecs, txnGate, src, shutdownSignal, sessionID); err != nil {
+			fmt.Printf("Replay loop exited with error: %s\n", err)
+			close(stopHeartbeat)
+			os.Exit(1)
+		}
+		close(stopHeartbeat)
+		return
+	}
+
 	// --- Start the Ticker and Production Loop ---
+	if rateMsgsPs <= 0 {
+		fmt.Printf("❌ Error: -rate must be > 0\n")
+		os.Exit(1)
+	}
 	interval := time.Duration(1000/rateMsgsPs) * time.Millisecond
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -204,10 +290,21 @@ func main() {
 	for {
 		select {
 		case <-ticker.C:
-			// Pass the format to the production function
-			produceMessage(p, formatType)
-		case e := <-p.Events():
-			_ = e
+			// One tick advances the whole correlated basket; fan each
+			// symbol's price out to its own topic from this one goroutine.
+			for _, sym := range sim.Step() {
+				produceMessage(p, codecs, sym.Name, sym.LastPrice)
+				txnGate.tick()
+			}
+		case sig := <-shutdownSignal:
+			log.Printf("Received %v, shutting down gracefully...", sig)
+			close(stopHeartbeat)
+			// SESSION_END must be produced before Close commits the final
+			// transaction — producing after commit hits a closed transaction.
+			emitControlMessage(p, sessionID, pb.StockControl_SESSION_END)
+			txnGate.Close()
+			p.Flush(shutdownTimeoutMs)
+			return
 		}
 	}
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+var (
+	compressionType   string
+	lingerMs          int
+	batchSize         int
+	acks              string
+	enableIdempotence bool
+	transactionalID   string
+	transactionSize   int
+)
+
+func init() {
+	flag.StringVar(&compressionType, "compression", "none", "OPTIONAL: Compression codec for produced batches. One of: none, gzip, snappy, lz4, zstd.")
+	flag.IntVar(&lingerMs, "linger-ms", 0, "OPTIONAL: Time (ms) to wait for batching before sending a produce request.")
+	flag.IntVar(&batchSize, "batch-size", 0, "OPTIONAL: Maximum size (bytes) of a batched produce request.")
+	flag.StringVar(&acks, "acks", "", "OPTIONAL: Required acks for a produce request (0, 1, or all).")
+	flag.BoolVar(&enableIdempotence, "enable-idempotence", false, "OPTIONAL: Enable idempotent delivery (no duplicate/out-of-order messages per partition).")
+	flag.StringVar(&transactionalID, "transactional-id", "", "OPTIONAL: Transactional ID. When set, the producer wraps every -transaction-size messages in a Kafka transaction.")
+	flag.IntVar(&transactionSize, "transaction-size", 100, "OPTIONAL: Number of messages committed per Kafka transaction when -transactional-id is set.")
+}
+
+// buildProducerConfig assembles the librdkafka ConfigMap from the
+// compression/batching/delivery flags, leaving anything unset so
+// librdkafka's own defaults apply.
+func buildProducerConfig() (*kafka.ConfigMap, error) {
+	cm := &kafka.ConfigMap{"bootstrap.servers": brokerAddr}
+
+	if compressionType != "" && compressionType != "none" {
+		if err := cm.SetKey("compression.type", compressionType); err != nil {
+			return nil, fmt.Errorf("set compression.type: %w", err)
+		}
+	}
+	if lingerMs > 0 {
+		if err := cm.SetKey("linger.ms", lingerMs); err != nil {
+			return nil, fmt.Errorf("set linger.ms: %w", err)
+		}
+	}
+	if batchSize > 0 {
+		if err := cm.SetKey("batch.size", batchSize); err != nil {
+			return nil, fmt.Errorf("set batch.size: %w", err)
+		}
+	}
+	if acks != "" {
+		if err := cm.SetKey("acks", acks); err != nil {
+			return nil, fmt.Errorf("set acks: %w", err)
+		}
+	}
+	if enableIdempotence {
+		if err := cm.SetKey("enable.idempotence", true); err != nil {
+			return nil, fmt.Errorf("set enable.idempotence: %w", err)
+		}
+	}
+	if transactionalID != "" {
+		if err := cm.SetKey("transactional.id", transactionalID); err != nil {
+			return nil, fmt.Errorf("set transactional.id: %w", err)
+		}
+	}
+
+	return cm, nil
+}
+
+// logProducerSettings prints the resolved compression/batching/delivery
+// knobs to the startup banner so operators can confirm what's in effect.
+func logProducerSettings() {
+	log.Printf("   -> Compression: %s", compressionType)
+	if lingerMs > 0 {
+		log.Printf("   -> Linger: %dms", lingerMs)
+	}
+	if batchSize > 0 {
+		log.Printf("   -> Batch Size: %d bytes", batchSize)
+	}
+	if acks != "" {
+		log.Printf("   -> Acks: %s", acks)
+	}
+	log.Printf("   -> Idempotence: %v", enableIdempotence)
+	if transactionalID != "" {
+		log.Printf("   -> Transactional ID: %s (transaction size: %d messages)", transactionalID, transactionSize)
+	}
+}
+
+// transactionGate batches produced messages into Kafka transactions of
+// transactionSize when -transactional-id is set; it is a no-op otherwise.
+type transactionGate struct {
+	producer *kafka.Producer
+	enabled  bool
+	size     int
+	count    int
+}
+
+// newTransactionGate starts the producer's first transaction when
+// transactional delivery is enabled.
+func newTransactionGate(p *kafka.Producer) (*transactionGate, error) {
+	g := &transactionGate{producer: p, enabled: transactionalID != "", size: transactionSize}
+	if !g.enabled {
+		return g, nil
+	}
+
+	if err := p.InitTransactions(context.Background()); err != nil {
+		return nil, fmt.Errorf("init transactions: %w", err)
+	}
+	if err := p.BeginTransaction(); err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	return g, nil
+}
+
+// tick is called once per produced message; it commits the current
+// transaction and opens the next one every `size` messages.
+func (g *transactionGate) tick() {
+	if !g.enabled {
+		return
+	}
+	g.count++
+	if g.count < g.size {
+		return
+	}
+	if err := g.producer.CommitTransaction(context.Background()); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+	}
+	if err := g.producer.BeginTransaction(); err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+	}
+	g.count = 0
+}
+
+// Close commits whatever transaction is still open — a partial batch that
+// hasn't hit `size`, every message produced so far if it never did, or
+// just the trailing SESSION_END callers are expected to produce before
+// calling Close. A transaction is open for the gate's entire enabled
+// lifetime (tick only ever commits-and-reopens, never commits-and-stops),
+// so this must run unconditionally rather than only when count > 0.
+// Every exit path (signal-driven shutdown, replay EOF, replay read error)
+// must call this after producing SESSION_END and before Flush — producing
+// after Close would hit a closed transaction, and skipping Close leaves
+// the open transaction uncommitted until the broker aborts it on
+// transaction timeout, so a read_committed consumer never sees it.
+func (g *transactionGate) Close() {
+	if !g.enabled {
+		return
+	}
+	if err := g.producer.CommitTransaction(context.Background()); err != nil {
+		log.Printf("Failed to commit final transaction: %v", err)
+	}
+	g.count = 0
+}
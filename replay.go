@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	pb "warwickcodingsociety.com/producer/models/stockfeed"
+)
+
+var (
+	replayFile  string
+	replayMode  string
+	replaySpeed float64
+)
+
+func init() {
+	flag.StringVar(&replayFile, "replay", "", "OPTIONAL: Path to a CSV file (columns: timestamp, ticker, price) to replay instead of simulating prices. Disables -ticker/-config. (Parquet is not yet supported; a .parquet path is rejected.)")
+	flag.StringVar(&replayMode, "replay-mode", "realtime", "OPTIONAL: Replay pacing when -replay is set. One of: realtime (honor the file's original inter-arrival times), rate (produce at a fixed -rate).")
+	flag.Float64Var(&replaySpeed, "speed", 1.0, "OPTIONAL: Speed multiplier applied to realtime replay pacing (2.0 = twice as fast).")
+}
+
+// ReplayRow is one parsed row from a replay source.
+type ReplayRow struct {
+	Timestamp time.Time
+	Ticker    string
+	Price     float64
+}
+
+// ReplaySource streams ReplayRows one at a time, returning io.EOF once
+// exhausted. It is deliberately an io.Reader-level abstraction so a future
+// S3/GCS-backed source can be plugged in without touching the replay loop.
+type ReplaySource interface {
+	Next() (ReplayRow, error)
+}
+
+// csvReplaySource reads rows from a CSV io.Reader (columns: timestamp,
+// ticker, price).
+//
+// Parquet is not implemented this round: it pulls in a columnar-format
+// dependency this repo doesn't otherwise need, and CSV covers the replay
+// use case (small historical fixtures) that motivated this feature. A
+// Parquet-backed ReplaySource can be added alongside this one later
+// without changing runReplayLoop.
+type csvReplaySource struct {
+	reader *csv.Reader
+}
+
+func newCSVReplaySource(r io.Reader) (*csvReplaySource, error) {
+	csvReader := csv.NewReader(r)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read replay header: %w", err)
+	}
+	if len(header) < 3 {
+		return nil, fmt.Errorf("replay file must have columns: timestamp, ticker, price")
+	}
+	return &csvReplaySource{reader: csvReader}, nil
+}
+
+func (s *csvReplaySource) Next() (ReplayRow, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		return ReplayRow{}, err
+	}
+	if len(record) < 3 {
+		return ReplayRow{}, fmt.Errorf("replay row has %d columns, want 3", len(record))
+	}
+
+	tsNanos, err := strconv.ParseInt(strings.TrimSpace(record[0]), 10, 64)
+	if err != nil {
+		return ReplayRow{}, fmt.Errorf("parse timestamp %q: %w", record[0], err)
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+	if err != nil {
+		return ReplayRow{}, fmt.Errorf("parse price %q: %w", record[2], err)
+	}
+
+	return ReplayRow{
+		Timestamp: time.Unix(0, tsNanos),
+		Ticker:    strings.TrimSpace(record[1]),
+		Price:     price,
+	}, nil
+}
+
+// openReplaySource opens -replay and returns the ReplaySource appropriate
+// for its extension, plus the underlying Closer.
+//
+// NOTE: only CSV is implemented. Parquet support is scoped out of this
+// change; a .parquet path fails fast here rather than being silently
+// misread as CSV.
+func openReplaySource(path string) (ReplaySource, io.Closer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open replay file: %w", err)
+	}
+
+	if strings.HasSuffix(path, ".parquet") {
+		f.Close()
+		return nil, nil, fmt.Errorf("parquet replay sources are not implemented in this version; use CSV")
+	}
+
+	src, err := newCSVReplaySource(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return src, f, nil
+}
+
+// runReplayLoop streams rows from src and produces each to its own
+// ticker's topic, honoring -replay-mode, until the source is exhausted or
+// a shutdown signal arrives.
+func runReplayLoop(p *kafka.Producer, codecs *codecResolver, txnGate *transactionGate, src ReplaySource, shutdownSignal <-chan os.Signal, sessionID int64) error {
+	// -rate only matters (and is only required) in "rate" mode; "realtime"
+	// mode paces itself off the file's own timestamps via -speed.
+	var rateInterval time.Duration
+	if replayMode == "rate" {
+		if rateMsgsPs <= 0 {
+			return fmt.Errorf("-rate must be > 0 when -replay-mode=rate")
+		}
+		rateInterval = time.Duration(1000/rateMsgsPs) * time.Millisecond
+	}
+
+	var lastRowTime time.Time
+	first := true
+
+	for {
+		select {
+		case sig := <-shutdownSignal:
+			drainAndShutdown(p, txnGate, sessionID, sig)
+			return nil
+		default:
+		}
+
+		row, err := src.Next()
+		if err == io.EOF {
+			log.Printf("Replay finished: reached end of %s", replayFile)
+			emitControlMessage(p, sessionID, pb.StockControl_SESSION_END)
+			txnGate.Close()
+			p.Flush(shutdownTimeoutMs)
+			return nil
+		}
+		if err != nil {
+			log.Printf("Failed to read replay row: %v", err)
+			emitControlMessage(p, sessionID, pb.StockControl_SESSION_END)
+			txnGate.Close()
+			p.Flush(shutdownTimeoutMs)
+			return err
+		}
+
+		var wait time.Duration
+		switch replayMode {
+		case "rate":
+			wait = rateInterval
+		default: // realtime
+			if !first {
+				wait = row.Timestamp.Sub(lastRowTime)
+				if replaySpeed > 0 {
+					wait = time.Duration(float64(wait) / replaySpeed)
+				}
+			}
+			lastRowTime = row.Timestamp
+			first = false
+		}
+
+		// Realtime gaps between historical rows can be minutes or hours;
+		// race the wait against shutdownSignal instead of sleeping
+		// unconditionally so Ctrl+C is still honored mid-wait.
+		if wait > 0 {
+			select {
+			case sig := <-shutdownSignal:
+				drainAndShutdown(p, txnGate, sessionID, sig)
+				return nil
+			case <-time.After(wait):
+			}
+		}
+
+		produceMessage(p, codecs, row.Ticker, row.Price)
+		txnGate.tick()
+	}
+}
+
+// drainAndShutdown emits SESSION_END (while the transaction, if any, is
+// still open), commits any in-flight transaction, and flushes in-flight
+// deliveries before a replay loop exits early.
+func drainAndShutdown(p *kafka.Producer, txnGate *transactionGate, sessionID int64, sig os.Signal) {
+	log.Printf("Received %v during replay, shutting down gracefully...", sig)
+	emitControlMessage(p, sessionID, pb.StockControl_SESSION_END)
+	txnGate.Close()
+	p.Flush(shutdownTimeoutMs)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// schemaRegistryClient is a minimal Confluent Schema Registry client: just
+// enough to register a schema for a subject and get back the ID that goes
+// into the Confluent wire-format header. It doesn't cache anything itself;
+// codecResolver registers once per topic and caches the resulting codec.
+type schemaRegistryClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newSchemaRegistryClient(baseURL string) *schemaRegistryClient {
+	return &schemaRegistryClient{baseURL: baseURL, http: &http.Client{}}
+}
+
+type registerSchemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type registerSchemaResponse struct {
+	ID uint32 `json:"id"`
+}
+
+// register posts schema under subject and returns the schema ID assigned
+// by the registry. schemaType is one of "AVRO" (the registry's default,
+// may be omitted) or "PROTOBUF".
+func (c *schemaRegistryClient) register(subject, schema, schemaType string) (uint32, error) {
+	reqBody, err := json.Marshal(registerSchemaRequest{Schema: schema, SchemaType: schemaType})
+	if err != nil {
+		return 0, fmt.Errorf("marshal schema registration request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/subjects/%s/versions", c.baseURL, subject)
+	resp, err := c.http.Post(url, "application/vnd.schemaregistry.v1+json", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("register schema for subject %s: %w", subject, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("schema registry returned status %d for subject %s", resp.StatusCode, subject)
+	}
+
+	var parsed registerSchemaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return 0, fmt.Errorf("decode schema registry response: %w", err)
+	}
+	return parsed.ID, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Symbol is one member of the simulated universe: its mean-reversion
+// parameters plus the most recently generated price.
+type Symbol struct {
+	Name      string  `json:"name"`
+	Mean      float64 `json:"mean"`
+	Reversion float64 `json:"reversion"`
+	Vol       float64 `json:"vol"`
+	LastPrice float64 `json:"lastPrice"`
+}
+
+// UniverseConfig describes the full tradable universe: the symbols and the
+// correlation matrix between their random shocks. rho[i][j] is the
+// correlation between symbol i and symbol j; the diagonal must be 1.
+type UniverseConfig struct {
+	Symbols     []Symbol    `json:"symbols"`
+	Correlation [][]float64 `json:"correlation"`
+}
+
+// loadUniverseConfig reads and validates a universe config from a JSON file.
+func loadUniverseConfig(path string) (*UniverseConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read universe config: %w", err)
+	}
+
+	var cfg UniverseConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse universe config: %w", err)
+	}
+
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("universe config defines no symbols")
+	}
+	if len(cfg.Correlation) != len(cfg.Symbols) {
+		return nil, fmt.Errorf("correlation matrix size (%d) does not match symbol count (%d)", len(cfg.Correlation), len(cfg.Symbols))
+	}
+	for i, row := range cfg.Correlation {
+		if len(row) != len(cfg.Symbols) {
+			return nil, fmt.Errorf("correlation matrix row %d has %d entries, want %d", i, len(row), len(cfg.Symbols))
+		}
+	}
+	for i := range cfg.Symbols {
+		if cfg.Symbols[i].LastPrice == 0 {
+			cfg.Symbols[i].LastPrice = cfg.Symbols[i].Mean
+		}
+	}
+
+	return &cfg, nil
+}
+
+// Simulator advances a correlated basket of symbols one tick at a time.
+type Simulator struct {
+	symbols []Symbol
+	chol    [][]float64 // lower-triangular Cholesky factor of the correlation matrix
+}
+
+// newSimulator Cholesky-decomposes the universe's correlation matrix once
+// up front so each Step only has to draw an i.i.d. normal vector and
+// multiply it through.
+func newSimulator(cfg *UniverseConfig) (*Simulator, error) {
+	chol, err := cholesky(cfg.Correlation)
+	if err != nil {
+		return nil, fmt.Errorf("decompose correlation matrix: %w", err)
+	}
+	return &Simulator{symbols: cfg.Symbols, chol: chol}, nil
+}
+
+// cholesky computes the lower-triangular factor L such that L*L^T == rho.
+func cholesky(rho [][]float64) ([][]float64, error) {
+	n := len(rho)
+	L := make([][]float64, n)
+	for i := range L {
+		L[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := 0; k < j; k++ {
+				sum += L[i][k] * L[j][k]
+			}
+			if i == j {
+				diag := rho[i][i] - sum
+				if diag < 0 {
+					return nil, fmt.Errorf("correlation matrix is not positive semi-definite at row %d", i)
+				}
+				L[i][j] = math.Sqrt(diag)
+			} else {
+				if L[j][j] == 0 {
+					return nil, fmt.Errorf("zero pivot in correlation matrix at row %d", j)
+				}
+				L[i][j] = (rho[i][j] - sum) / L[j][j]
+			}
+		}
+	}
+
+	return L, nil
+}
+
+// Step draws one correlated shock per symbol (eps = L*z) and applies the
+// mean-reversion update P_i += (mean_i - P_i)*reversion_i + vol_i*eps_i,
+// returning the updated symbol table.
+func (s *Simulator) Step() []Symbol {
+	n := len(s.symbols)
+
+	z := make([]float64, n)
+	for i := range z {
+		z[i] = rand.NormFloat64()
+	}
+
+	eps := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := 0.0
+		for k := 0; k <= i; k++ {
+			sum += s.chol[i][k] * z[k]
+		}
+		eps[i] = sum
+	}
+
+	for i := range s.symbols {
+		sym := &s.symbols[i]
+		sym.LastPrice += (sym.Mean-sym.LastPrice)*sym.Reversion + sym.Vol*eps[i]
+		if sym.LastPrice <= 0 {
+			sym.LastPrice = 0.01
+		}
+	}
+
+	return s.symbols
+}